@@ -43,8 +43,17 @@ func WaitCertificateIssuedReady(client certclient.Interface, name string, ns str
 	return nil
 }
 
-// CleanCertSecrets removes all secrets which hold a TLS certificate issued by cert-manager
+// CleanCertSecrets removes all secrets which hold a TLS certificate issued by cert-manager in ns.
 func CleanCertSecrets(client kubernetes.Interface, ns string) error {
+	return CleanCertSecretsFrom(client, ns, ns)
+}
+
+// CleanCertSecretsFrom removes all secrets which hold a TLS certificate issued by cert-manager in
+// ns, on behalf of fromNamespace. Pass ns as fromNamespace for the common case of a reconciler
+// cleaning up its own namespace (CleanCertSecrets does exactly this); when they differ (a tenant
+// namespace cleaning up a cert secret it doesn't own, e.g. a shared wildcard cert in a platform
+// namespace), each secret is checked via CheckReferenceGrant before being deleted.
+func CleanCertSecretsFrom(client kubernetes.Interface, ns string, fromNamespace string) error {
 	// delete the tls related secrets so we dont reuse old ones when switching from http to https
 	secrets, err := client.CoreV1().Secrets(ns).List(metav1.ListOptions{})
 	if err != nil {
@@ -52,6 +61,10 @@ func CleanCertSecrets(client kubernetes.Interface, ns string) error {
 	}
 	for _, s := range secrets.Items {
 		if strings.HasPrefix(s.Name, certSecretPrefix) {
+			ref := CertificateReference{Name: s.Name, Namespace: ns}
+			if err := CheckReferenceGrant(client, ref, fromNamespace); err != nil {
+				return err
+			}
 			err := client.CoreV1().Secrets(ns).Delete(s.Name, &metav1.DeleteOptions{})
 			if err != nil {
 				return fmt.Errorf("failed to delete tls secret %s: %v", s.Name, err)
@@ -0,0 +1,204 @@
+package pki
+
+import (
+	"io/ioutil"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	certmng "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	certclient "github.com/jetstack/cert-manager/pkg/client/clientset/versioned"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/tools/clientcmd/api/latest"
+)
+
+// KeyAlgorithm is the private key algorithm requested for a client certificate.
+type KeyAlgorithm string
+
+const (
+	// RSA requests an RSA private key
+	RSA KeyAlgorithm = "RSA"
+	// ECDSAP256 requests an ECDSA private key on curve P-256
+	ECDSAP256 KeyAlgorithm = "ECDSA-P256"
+)
+
+// ClientCertSpec describes a client certificate to be issued for machine-to-machine auth, e.g. a
+// jx CLI user, a webhook, or a bot ServiceAccount.
+type ClientCertSpec struct {
+	// CommonName becomes the certificate's CN, and the Kubernetes API server's default username
+	CommonName string
+	// Organization becomes the certificate's O entries, and maps to RBAC group membership
+	Organization []string
+	KeyAlgorithm KeyAlgorithm
+	Lifetime     time.Duration
+}
+
+// ClientCertificateBundle is the result of issuing or renewing a client certificate: the PEM
+// encoded cert, private key and CA bundle backing it.
+type ClientCertificateBundle struct {
+	Certificate Certificate
+	CertPEM     []byte
+	KeyPEM      []byte
+	CAPEM       []byte
+}
+
+// IssueClientCertificate requests a client certificate from cert-manager for spec, waits for it to
+// become ready, and returns the resulting PEM bundle.
+func IssueClientCertificate(kubeClient kubernetes.Interface, certClient certclient.Interface, ns string, name string, issuerRef certmng.ObjectReference, spec ClientCertSpec) (*ClientCertificateBundle, error) {
+	cert := clientCertificateResource(ns, name, issuerRef, spec)
+	if _, err := certClient.Certmanager().Certificates(ns).Create(cert); err != nil {
+		return nil, errors.Wrapf(err, "creating client certificate %s/%s", ns, name)
+	}
+	if err := WaitCertificateIssuedReady(certClient, name, ns, 2*time.Minute); err != nil {
+		return nil, err
+	}
+	return readClientCertificateBundle(kubeClient, ns, name)
+}
+
+// RenewClientCertificate forces cert-manager to re-issue the client certificate name in ns,
+// rotating its backing Secret in-place, and returns the refreshed PEM bundle. It uses the same
+// delete-the-Secret trigger as RotationManager, and waits for the new certificate's NotAfter to
+// actually advance before returning, rather than assuming the trigger alone means success.
+func RenewClientCertificate(kubeClient kubernetes.Interface, certClient certclient.Interface, ns string, name string) (*ClientCertificateBundle, error) {
+	cert, err := certClient.Certmanager().Certificates(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting client certificate %s/%s", ns, name)
+	}
+	_, previousNotAfter, err := certSecretValidity(kubeClient, ns, secretNameFor(cert))
+	if err != nil {
+		return nil, err
+	}
+	if err := triggerReissue(kubeClient, cert); err != nil {
+		return nil, err
+	}
+	rotated, err := waitForSecretRotation(kubeClient, ns, secretNameFor(cert), previousNotAfter, defaultReissueTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		return nil, errors.Errorf("renewal of client certificate %s/%s did not complete within %s", ns, name, defaultReissueTimeout)
+	}
+	if err := WaitCertificateIssuedReady(certClient, name, ns, defaultReissueTimeout); err != nil {
+		return nil, err
+	}
+	return readClientCertificateBundle(kubeClient, ns, name)
+}
+
+func clientCertificateResource(ns string, name string, issuerRef certmng.ObjectReference, spec ClientCertSpec) *certmng.Certificate {
+	keyAlgorithm := certmng.RSAKeyAlgorithm
+	if spec.KeyAlgorithm == ECDSAP256 {
+		keyAlgorithm = certmng.ECDSAKeyAlgorithm
+	}
+	cert := &certmng.Certificate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+		},
+		Spec: certmng.CertificateSpec{
+			SecretName:   name,
+			CommonName:   spec.CommonName,
+			Organization: spec.Organization,
+			KeyAlgorithm: keyAlgorithm,
+			Usages:       []certmng.KeyUsage{certmng.UsageClientAuth},
+			IssuerRef:    issuerRef,
+		},
+	}
+	if spec.Lifetime > 0 {
+		cert.Spec.Duration = &metav1.Duration{Duration: spec.Lifetime}
+	}
+	return cert
+}
+
+func readClientCertificateBundle(kubeClient kubernetes.Interface, ns string, name string) (*ClientCertificateBundle, error) {
+	secret, err := kubeClient.CoreV1().Secrets(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting secret %s/%s", ns, name)
+	}
+	return &ClientCertificateBundle{
+		Certificate: Certificate{Name: name, Namespace: ns},
+		CertPEM:     secret.Data["tls.crt"],
+		KeyPEM:      secret.Data["tls.key"],
+		CAPEM:       secret.Data["ca.crt"],
+	}, nil
+}
+
+// WriteKubeconfig writes a client-cert kubeconfig built from bundle to path, authenticating
+// against apiServerURL with caBundle.
+func WriteKubeconfig(bundle *ClientCertificateBundle, apiServerURL string, caBundle []byte, path string) error {
+	clusterName := bundle.Certificate.Name
+	config := clientcmdapi.Config{
+		Clusters: map[string]*clientcmdapi.Cluster{
+			clusterName: {
+				Server:                   apiServerURL,
+				CertificateAuthorityData: caBundle,
+			},
+		},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{
+			clusterName: {
+				ClientCertificateData: bundle.CertPEM,
+				ClientKeyData:         bundle.KeyPEM,
+			},
+		},
+		Contexts: map[string]*clientcmdapi.Context{
+			clusterName: {
+				Cluster:  clusterName,
+				AuthInfo: clusterName,
+			},
+		},
+		CurrentContext: clusterName,
+	}
+
+	data, err := runtimeEncodeKubeconfig(&config)
+	if err != nil {
+		return errors.Wrap(err, "encoding kubeconfig")
+	}
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		return errors.Wrapf(err, "writing kubeconfig to %q", path)
+	}
+	return nil
+}
+
+func runtimeEncodeKubeconfig(config *clientcmdapi.Config) ([]byte, error) {
+	versioned, err := latest.Scheme.ConvertToVersion(config, latest.ExternalVersion)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.Encode(latest.Codec, versioned)
+}
+
+// WatchKubeconfigFile watches path for changes and invokes onChange whenever it is rewritten, so a
+// long-running controller can hot-reload a kubeconfig produced by RenewClientCertificate. The
+// caller stops the watch by calling the returned stop function.
+func WatchKubeconfigFile(path string, onChange func()) (stop func() error, err error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, errors.Wrap(err, "creating file watcher")
+	}
+	if err := watcher.Add(path); err != nil {
+		watcher.Close()
+		return nil, errors.Wrapf(err, "watching %q", path)
+	}
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+					onChange()
+				}
+			case watchErr, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logrus.Warnf("error watching kubeconfig %q: %v", path, watchErr)
+			}
+		}
+	}()
+	return watcher.Close, nil
+}
@@ -0,0 +1,46 @@
+package pki
+
+import (
+	"testing"
+	"time"
+
+	certmng "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientCertificateResourceDefaultsToRSA(t *testing.T) {
+	cert := clientCertificateResource("jx", "bot", certmng.ObjectReference{Name: "letsencrypt"}, ClientCertSpec{
+		CommonName:   "bot",
+		Organization: []string{"system:masters"},
+	})
+	assert.Equal(t, certmng.RSAKeyAlgorithm, cert.Spec.KeyAlgorithm)
+	assert.Equal(t, "bot", cert.Spec.CommonName)
+	assert.Equal(t, []string{"system:masters"}, cert.Spec.Organization)
+	assert.Contains(t, cert.Spec.Usages, certmng.UsageClientAuth)
+	assert.Equal(t, "bot", cert.Spec.SecretName)
+}
+
+func TestClientCertificateResourceRequestsECDSAWhenAsked(t *testing.T) {
+	cert := clientCertificateResource("jx", "bot", certmng.ObjectReference{Name: "letsencrypt"}, ClientCertSpec{
+		CommonName:   "bot",
+		KeyAlgorithm: ECDSAP256,
+	})
+	assert.Equal(t, certmng.ECDSAKeyAlgorithm, cert.Spec.KeyAlgorithm)
+}
+
+func TestClientCertificateResourceMapsLifetimeToDuration(t *testing.T) {
+	cert := clientCertificateResource("jx", "bot", certmng.ObjectReference{Name: "letsencrypt"}, ClientCertSpec{
+		CommonName: "bot",
+		Lifetime:   90 * 24 * time.Hour,
+	})
+	if assert.NotNil(t, cert.Spec.Duration) {
+		assert.Equal(t, 90*24*time.Hour, cert.Spec.Duration.Duration)
+	}
+}
+
+func TestClientCertificateResourceLeavesDurationNilWhenLifetimeUnset(t *testing.T) {
+	cert := clientCertificateResource("jx", "bot", certmng.ObjectReference{Name: "letsencrypt"}, ClientCertSpec{
+		CommonName: "bot",
+	})
+	assert.Nil(t, cert.Spec.Duration)
+}
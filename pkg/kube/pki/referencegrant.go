@@ -0,0 +1,77 @@
+package pki
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// referenceGrantConfigMapName holds the cross-namespace allowlist consulted by CheckReferenceGrant.
+// It lives alongside the secret it protects: data["<secretName>"] is a comma-separated list of
+// namespaces (or "*") permitted to reference that secret.
+const referenceGrantConfigMapName = "tls-reference-grants"
+
+// CertificateReference points at a TLS Secret that may live in a different namespace from the
+// resource referencing it, mirroring the cross-namespace reference pattern used by Gateway API.
+type CertificateReference struct {
+	Name      string
+	Namespace string
+}
+
+// String returns the reference in a string format
+func (ref CertificateReference) String() string {
+	return fmt.Sprintf("%s/%s", ref.Namespace, ref.Name)
+}
+
+// ErrRefNotPermitted is returned when fromNamespace is not authorized to reference ref. Callers
+// should surface this as an InvalidCertificateRef/RefNotPermitted status on the referring resource.
+type ErrRefNotPermitted struct {
+	Ref           CertificateReference
+	FromNamespace string
+}
+
+// Error implements error
+func (e *ErrRefNotPermitted) Error() string {
+	return fmt.Sprintf("namespace %q is not permitted to reference certificate secret %s", e.FromNamespace, e.Ref)
+}
+
+// IsRefNotPermitted reports whether err is (or wraps) an ErrRefNotPermitted.
+func IsRefNotPermitted(err error) bool {
+	_, ok := errors.Cause(err).(*ErrRefNotPermitted)
+	return ok
+}
+
+// CheckReferenceGrant verifies that fromNamespace is permitted to reference the TLS secret
+// identified by ref. References within the same namespace are always permitted; a cross-namespace
+// reference is permitted only if ref.Namespace has a tls-reference-grants ConfigMap whose entry
+// for ref.Name lists fromNamespace (or "*").
+func CheckReferenceGrant(client kubernetes.Interface, ref CertificateReference, fromNamespace string) error {
+	if ref.Namespace == "" || ref.Namespace == fromNamespace {
+		return nil
+	}
+	cm, err := client.CoreV1().ConfigMaps(ref.Namespace).Get(referenceGrantConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return &ErrRefNotPermitted{Ref: ref, FromNamespace: fromNamespace}
+		}
+		return errors.Wrapf(err, "getting reference grant configmap in namespace %q", ref.Namespace)
+	}
+	if !referenceGrantAllows(cm.Data[ref.Name], fromNamespace) {
+		return &ErrRefNotPermitted{Ref: ref, FromNamespace: fromNamespace}
+	}
+	return nil
+}
+
+func referenceGrantAllows(allowedNamespaces string, fromNamespace string) bool {
+	for _, ns := range strings.Split(allowedNamespaces, ",") {
+		ns = strings.TrimSpace(ns)
+		if ns == "*" || ns == fromNamespace {
+			return true
+		}
+	}
+	return false
+}
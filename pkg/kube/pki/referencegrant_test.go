@@ -0,0 +1,15 @@
+package pki
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReferenceGrantAllows(t *testing.T) {
+	assert.True(t, referenceGrantAllows("team-a,team-b", "team-b"))
+	assert.True(t, referenceGrantAllows("team-a, team-b", "team-b"))
+	assert.True(t, referenceGrantAllows("*", "anything"))
+	assert.False(t, referenceGrantAllows("team-a", "team-c"))
+	assert.False(t, referenceGrantAllows("", "team-a"))
+}
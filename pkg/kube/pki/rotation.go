@@ -0,0 +1,237 @@
+package pki
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	certmng "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	certclient "github.com/jetstack/cert-manager/pkg/client/clientset/versioned"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+// renewalFraction is the fraction of a certificate's lifetime that may remain before it is
+// considered due for renewal, e.g. 1/3 means renewal is triggered once two thirds of the
+// certificate's lifetime has elapsed.
+const renewalFraction = 1.0 / 3.0
+
+// defaultReissueTimeout bounds how long checkOne waits for a triggered re-issue to actually land in
+// the Secret before giving up on reporting Rotated.
+const defaultReissueTimeout = 2 * time.Minute
+
+// RotationReason explains why a RotationEvent was emitted.
+type RotationReason string
+
+const (
+	// DueSoon means less than renewalFraction of the certificate's lifetime remains
+	DueSoon RotationReason = "DueSoon"
+	// Expired means the certificate's NotAfter has already passed
+	Expired RotationReason = "Expired"
+	// Rotated means a re-issue was triggered and the backing Secret's NotAfter was confirmed to
+	// have advanced
+	Rotated RotationReason = "Rotated"
+)
+
+// RotationEvent reports a certificate rotation decision or outcome, analogous to the Certificate
+// values sent by WatchCertificatesIssuedReady.
+type RotationEvent struct {
+	Certificate Certificate
+	Reason      RotationReason
+}
+
+// RotationManager watches certificates issued by cert-manager and proactively triggers renewal a
+// configurable window before expiry, so that ingresses don't silently serve expired certs when
+// cert-manager is stuck.
+type RotationManager struct {
+	KubeClient  kubernetes.Interface
+	CertClient  certclient.Interface
+	Namespace   string
+	CheckPeriod time.Duration
+	// ReissueTimeout bounds how long to wait for a triggered re-issue to land before giving up on
+	// reporting Rotated. Defaults to defaultReissueTimeout if zero.
+	ReissueTimeout time.Duration
+}
+
+// NewRotationManager creates a RotationManager that polls certificates in ns every checkPeriod.
+// If ns is empty, it polls certificates across the entire cluster.
+func NewRotationManager(kubeClient kubernetes.Interface, certClient certclient.Interface, ns string, checkPeriod time.Duration) *RotationManager {
+	return &RotationManager{
+		KubeClient:     kubeClient,
+		CertClient:     certClient,
+		Namespace:      ns,
+		CheckPeriod:    checkPeriod,
+		ReissueTimeout: defaultReissueTimeout,
+	}
+}
+
+// Start begins polling for certificates nearing or past expiry, emitting a RotationEvent for each
+// decision and for each re-issue it confirms. The caller can stop the manager by cancelling ctx.
+func (m *RotationManager) Start(ctx context.Context) <-chan RotationEvent {
+	events := make(chan RotationEvent)
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(m.CheckPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.checkAll(events)
+			}
+		}
+	}()
+	return events
+}
+
+func (m *RotationManager) checkAll(events chan<- RotationEvent) {
+	certs, err := m.CertClient.Certmanager().Certificates(m.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		logrus.Warnf("failed to list certificates in namespace %q: %v", m.Namespace, err)
+		return
+	}
+	for i := range certs.Items {
+		m.checkOne(&certs.Items[i], events)
+	}
+}
+
+func (m *RotationManager) checkOne(cert *certmng.Certificate, events chan<- RotationEvent) {
+	c := Certificate{Name: cert.GetName(), Namespace: cert.GetNamespace()}
+
+	notBefore, notAfter, err := m.secretValidity(cert)
+	if err != nil {
+		logrus.Warnf("failed to read validity period for certificate %s: %v", c, err)
+		return
+	}
+	if notAfter.IsZero() {
+		// the Secret doesn't exist yet, or has no tls.crt - nothing to rotate
+		return
+	}
+
+	reason := rotationReasonFor(notBefore, notAfter, time.Now())
+	if reason == "" {
+		return
+	}
+
+	events <- RotationEvent{Certificate: c, Reason: reason}
+
+	timeout := m.ReissueTimeout
+	if timeout <= 0 {
+		timeout = defaultReissueTimeout
+	}
+	if err := triggerReissue(m.KubeClient, cert); err != nil {
+		logrus.Warnf("failed to trigger re-issue of certificate %s (%s): %v", c, reason, err)
+		return
+	}
+	rotated, err := waitForSecretRotation(m.KubeClient, cert.GetNamespace(), secretNameFor(cert), notAfter, timeout)
+	if err != nil {
+		logrus.Warnf("failed to confirm re-issue of certificate %s (%s): %v", c, reason, err)
+		return
+	}
+	if !rotated {
+		logrus.Warnf("triggered re-issue of certificate %s (%s) but its Secret's NotAfter had not "+
+			"advanced within %s", c, reason, timeout)
+		return
+	}
+	events <- RotationEvent{Certificate: c, Reason: Rotated}
+}
+
+// rotationReasonFor decides, given a certificate's validity window and the current time, whether
+// it is Expired, DueSoon, or fine (empty RotationReason). It's a pure function so the threshold
+// math can be tested without standing up fake clientsets.
+func rotationReasonFor(notBefore time.Time, notAfter time.Time, now time.Time) RotationReason {
+	remaining := notAfter.Sub(now)
+	if remaining <= 0 {
+		return Expired
+	}
+	lifetime := notAfter.Sub(notBefore)
+	if lifetime > 0 && remaining < time.Duration(float64(lifetime)*renewalFraction) {
+		return DueSoon
+	}
+	return ""
+}
+
+// secretNameFor returns the Secret name backing cert.
+func secretNameFor(cert *certmng.Certificate) string {
+	if cert.Spec.SecretName != "" {
+		return cert.Spec.SecretName
+	}
+	return cert.GetName()
+}
+
+// secretValidity reads the NotBefore/NotAfter of the leaf certificate stored in cert's target
+// Secret.
+func (m *RotationManager) secretValidity(cert *certmng.Certificate) (notBefore time.Time, notAfter time.Time, err error) {
+	return certSecretValidity(m.KubeClient, cert.GetNamespace(), secretNameFor(cert))
+}
+
+// certSecretValidity reads the NotBefore/NotAfter of the leaf certificate stored in the named
+// Secret. It returns the zero Time pair, with no error, if the Secret has no tls.crt yet (e.g. it
+// was just deleted to trigger a re-issue and hasn't been recreated).
+func certSecretValidity(kubeClient kubernetes.Interface, ns string, secretName string) (notBefore time.Time, notAfter time.Time, err error) {
+	secret, err := kubeClient.CoreV1().Secrets(ns).Get(secretName, metav1.GetOptions{})
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.Wrapf(err, "getting secret %q in namespace %q", secretName, ns)
+	}
+	return certValidity(secret)
+}
+
+// certValidity parses the tls.crt entry of secret and returns its NotBefore/NotAfter, or the zero
+// Time pair if the secret has no tls.crt yet.
+func certValidity(secret *v1.Secret) (notBefore time.Time, notAfter time.Time, err error) {
+	data := secret.Data["tls.crt"]
+	if len(data) == 0 {
+		return time.Time{}, time.Time{}, nil
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("no PEM data found in secret %s/%s tls.crt", secret.Namespace, secret.Name)
+	}
+	x509Cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.Wrapf(err, "parsing certificate in secret %s/%s", secret.Namespace, secret.Name)
+	}
+	return x509Cert.NotBefore, x509Cert.NotAfter, nil
+}
+
+// triggerReissue asks cert-manager to re-issue the certificate backing cert by deleting its
+// target Secret - cert-manager's controller watches for the Secret going missing and reissues it,
+// unlike an annotation on the Certificate object, which cert-manager v1alpha1 does not treat as a
+// re-issue signal.
+func triggerReissue(kubeClient kubernetes.Interface, cert *certmng.Certificate) error {
+	secretName := secretNameFor(cert)
+	if err := kubeClient.CoreV1().Secrets(cert.GetNamespace()).Delete(secretName, &metav1.DeleteOptions{}); err != nil {
+		return errors.Wrapf(err, "deleting secret %q to trigger re-issue of certificate %s/%s", secretName, cert.GetNamespace(), cert.GetName())
+	}
+	return nil
+}
+
+// waitForSecretRotation polls the named Secret until its NotAfter advances past previousNotAfter,
+// or timeout elapses, so that callers only report a rotation once cert-manager has actually
+// re-issued the certificate rather than as soon as the trigger was sent.
+func waitForSecretRotation(kubeClient kubernetes.Interface, ns string, secretName string, previousNotAfter time.Time, timeout time.Duration) (bool, error) {
+	rotated := false
+	err := wait.PollImmediate(time.Second, timeout, func() (bool, error) {
+		_, notAfter, err := certSecretValidity(kubeClient, ns, secretName)
+		if err != nil {
+			// the Secret is commonly absent for a moment right after triggerReissue deletes it
+			return false, nil
+		}
+		if notAfter.After(previousNotAfter) {
+			rotated = true
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil && err != wait.ErrWaitTimeout {
+		return false, err
+	}
+	return rotated, nil
+}
@@ -0,0 +1,39 @@
+package pki
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotationReasonForExpired(t *testing.T) {
+	now := time.Now()
+	notBefore := now.Add(-48 * time.Hour)
+	notAfter := now.Add(-1 * time.Hour)
+	assert.Equal(t, Expired, rotationReasonFor(notBefore, notAfter, now))
+}
+
+func TestRotationReasonForDueSoon(t *testing.T) {
+	now := time.Now()
+	// 90h lifetime, 10h (< 1/3) remaining
+	notBefore := now.Add(-80 * time.Hour)
+	notAfter := now.Add(10 * time.Hour)
+	assert.Equal(t, DueSoon, rotationReasonFor(notBefore, notAfter, now))
+}
+
+func TestRotationReasonForHealthy(t *testing.T) {
+	now := time.Now()
+	notBefore := now.Add(-10 * time.Hour)
+	notAfter := now.Add(80 * time.Hour)
+	assert.Equal(t, RotationReason(""), rotationReasonFor(notBefore, notAfter, now))
+}
+
+func TestRotationReasonForUsesNotBeforeNotCreationTimestamp(t *testing.T) {
+	// a certificate renewed many cycles ago but still within its current, short validity window
+	// must not be treated as having a long lifetime just because it is an old object.
+	now := time.Now()
+	notBefore := now.Add(-21 * time.Hour)
+	notAfter := now.Add(9 * time.Hour) // 30h lifetime, 9h (< 1/3) remaining: due soon
+	assert.Equal(t, DueSoon, rotationReasonFor(notBefore, notAfter, now))
+}
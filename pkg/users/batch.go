@@ -0,0 +1,190 @@
+package users
+
+import (
+	"sync"
+
+	jenkinsv1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/log"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jenkins-x/jx/pkg/gits"
+)
+
+// batchWorkerCount bounds the number of concurrent GitProvider.UserInfo calls made while resolving
+// the residual set of a batch that isn't already known to users.jenkins.io
+const batchWorkerCount = 10
+
+// GitUserSliceAsUserDetailsSliceBatch resolves users in a single pass: it lists users.jenkins.io
+// once and indexes the results by (providerKey, login) and by email, resolves every entry against
+// those indexes, and only falls back to GitProvider.UserInfo (via a bounded worker pool) for the
+// residual set that the indexes can't answer. Any label backfill discovered along the way is
+// written back in one Update per user, rather than once per resolution as GitUserAsUser does.
+// Entries that neither the indexes nor the git provider can match are resolved one at a time
+// through GitUserAsUser, which lists users.jenkins.io again for each of them.
+func (r *GitUserResolver) GitUserSliceAsUserDetailsSliceBatch(users []gits.GitUser) ([]jenkinsv1.UserDetails, error) {
+	if len(users) == 0 {
+		return []jenkinsv1.UserDetails{}, nil
+	}
+	r.ensureCache()
+	providerKey := r.GitProviderKey()
+
+	all, err := r.JXClient.JenkinsV1().Users(r.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	byLogin := make(map[string]*jenkinsv1.User, len(all.Items))
+	byEmail := make(map[string]*jenkinsv1.User, len(all.Items))
+	for i := range all.Items {
+		u := &all.Items[i]
+		for _, a := range u.Spec.Accounts {
+			if a.Provider == providerKey {
+				byLogin[a.ID] = u
+			}
+		}
+		if u.Spec.Email != "" {
+			byEmail[u.Spec.Email] = u
+		}
+	}
+
+	answer := make([]jenkinsv1.UserDetails, len(users))
+	resolved := make([]bool, len(users))
+	residual := make([]int, 0)
+
+	for i, gu := range users {
+		if gu.Login != "" {
+			if u, ok := byLogin[gu.Login]; ok {
+				answer[i] = u.Spec
+				resolved[i] = true
+				continue
+			}
+		}
+		if gu.Email != "" {
+			if u, ok := byEmail[gu.Email]; ok {
+				answer[i] = u.Spec
+				resolved[i] = true
+				continue
+			}
+		}
+		residual = append(residual, i)
+	}
+
+	if len(residual) > 0 {
+		toLabel := r.resolveResidualBatch(users, residual, byEmail, answer, resolved)
+		if err := r.backfillLabels(toLabel, providerKey, answer); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, ok := range resolved {
+		if ok {
+			continue
+		}
+		// no index and no git provider match - fall back to the single-item path, which creates
+		// a new user using the best information available
+		u, err := r.GitUserAsUser(&users[i])
+		if err != nil {
+			return nil, err
+		}
+		answer[i] = u.Spec
+	}
+
+	return answer, nil
+}
+
+// residualResult is one worker's outcome for a single residual index: the user matched by email,
+// and the login that matched it, needed to record the new AccountReference.
+type residualResult struct {
+	index int
+	login string
+	user  *jenkinsv1.User
+}
+
+// residualMatch groups every residual index that resolved to the same user, so backfillLabels can
+// write the updated Spec (post-Update, with the new AccountReference) back into all of them.
+type residualMatch struct {
+	login   string
+	indices []int
+}
+
+// resolveResidualBatch resolves the entries at residual indices against the git provider, using a
+// bounded worker pool, matching each GitProvider.UserInfo result against byEmail. It marks resolved
+// entries in resolved and returns, per distinct user discovered, the login and residual indices
+// that matched it; answer is populated for these indices by backfillLabels once the
+// AccountReference has actually been recorded, not before.
+func (r *GitUserResolver) resolveResidualBatch(users []gits.GitUser, residual []int, byEmail map[string]*jenkinsv1.User,
+	answer []jenkinsv1.UserDetails, resolved []bool) map[*jenkinsv1.User]*residualMatch {
+
+	work := make(chan int)
+	results := make(chan residualResult, len(residual))
+
+	var wg sync.WaitGroup
+	for w := 0; w < batchWorkerCount; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				gitUser := r.lookupGitProvider(users[i].Login)
+				if gitUser == nil {
+					continue
+				}
+				if u, ok := byEmail[gitUser.Email]; ok {
+					results <- residualResult{index: i, login: users[i].Login, user: u}
+				}
+			}
+		}()
+	}
+	go func() {
+		for _, i := range residual {
+			work <- i
+		}
+		close(work)
+	}()
+	wg.Wait()
+	close(results)
+
+	toLabel := make(map[*jenkinsv1.User]*residualMatch)
+	for res := range results {
+		resolved[res.index] = true
+		match, ok := toLabel[res.user]
+		if !ok {
+			match = &residualMatch{login: res.login}
+			toLabel[res.user] = match
+		}
+		match.indices = append(match.indices, res.index)
+	}
+	return toLabel
+}
+
+// backfillLabels records the new AccountReference and provider label for each user discovered by
+// resolveResidualBatch, writing one Update per user rather than once per resolved login, and only
+// then fills in answer for the indices that matched that user - so the returned UserDetails always
+// carries the AccountReference this backfill just added.
+func (r *GitUserResolver) backfillLabels(toLabel map[*jenkinsv1.User]*residualMatch, providerKey string, answer []jenkinsv1.UserDetails) error {
+	for u, match := range toLabel {
+		if u.Labels == nil || u.Labels[providerKey] != match.login {
+			if u.Spec.Accounts == nil {
+				u.Spec.Accounts = make([]jenkinsv1.AccountReference, 0)
+			}
+			u.Spec.Accounts = append(u.Spec.Accounts, jenkinsv1.AccountReference{
+				ID:       match.login,
+				Provider: providerKey,
+			})
+			if u.Labels == nil {
+				u.Labels = make(map[string]string)
+			}
+			u.Labels[providerKey] = match.login
+			updated, err := r.JXClient.JenkinsV1().Users(r.Namespace).Update(u)
+			if err != nil {
+				return err
+			}
+			u = updated
+			log.Infof("Adding label %s=%s to user %s in users.jenkins.io\n", providerKey, match.login, u.Name)
+		}
+		for _, i := range match.indices {
+			answer[i] = u.Spec
+		}
+	}
+	return nil
+}
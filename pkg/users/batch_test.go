@@ -0,0 +1,119 @@
+package users
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	jenkinsv1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/gits"
+
+	fake "github.com/jenkins-x/jx/pkg/client/clientset/versioned/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// stubGitProvider implements gits.GitProvider for the batch test cases that are satisfied entirely
+// by the users.jenkins.io index and never need to reach a real GitProvider. Embedding the (nil)
+// interface satisfies every method this test doesn't override; calling one of those would panic,
+// which is fine since these tests never exercise the provider fallback path.
+type stubGitProvider struct {
+	gits.GitProvider
+	kind  string
+	users map[string]*gits.GitUser
+}
+
+func (s *stubGitProvider) Kind() string {
+	return s.kind
+}
+
+func (s *stubGitProvider) UserInfo(login string) *gits.GitUser {
+	return s.users[login]
+}
+
+const testProviderKind = "test"
+
+var testProviderKey = "jenkins.io/git-" + testProviderKind + "-userid"
+
+func newTestResolver(jxClient *fake.Clientset) *GitUserResolver {
+	return &GitUserResolver{
+		GitProvider: &stubGitProvider{kind: testProviderKind},
+		JXClient:    jxClient,
+		Namespace:   "jx",
+	}
+}
+
+func TestGitUserSliceAsUserDetailsSliceBatchResolvesFromLoginIndex(t *testing.T) {
+	jxClient := fake.NewSimpleClientset(&jenkinsv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "alice"},
+		Spec: jenkinsv1.UserDetails{
+			Login: "alice",
+			Email: "alice@example.com",
+			Accounts: []jenkinsv1.AccountReference{
+				{Provider: testProviderKey, ID: "alice"},
+			},
+		},
+	})
+	r := newTestResolver(jxClient)
+
+	details, err := r.GitUserSliceAsUserDetailsSliceBatch([]gits.GitUser{{Login: "alice"}})
+	assert.NoError(t, err)
+	assert.Len(t, details, 1)
+	assert.Equal(t, "alice@example.com", details[0].Email)
+}
+
+func TestGitUserSliceAsUserDetailsSliceBatchResolvesFromEmailIndex(t *testing.T) {
+	jxClient := fake.NewSimpleClientset(&jenkinsv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "bob"},
+		Spec: jenkinsv1.UserDetails{
+			Login: "bob",
+			Email: "bob@example.com",
+		},
+	})
+	r := newTestResolver(jxClient)
+
+	details, err := r.GitUserSliceAsUserDetailsSliceBatch([]gits.GitUser{{Email: "bob@example.com"}})
+	assert.NoError(t, err)
+	assert.Len(t, details, 1)
+	assert.Equal(t, "bob@example.com", details[0].Email)
+}
+
+func TestGitUserSliceAsUserDetailsSliceBatchBackfillsResidualAccountReference(t *testing.T) {
+	jxClient := fake.NewSimpleClientset(&jenkinsv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "carol"},
+		Spec: jenkinsv1.UserDetails{
+			Login: "carol",
+			Email: "carol@example.com",
+		},
+	})
+	r := &GitUserResolver{
+		GitProvider: &stubGitProvider{
+			kind: testProviderKind,
+			users: map[string]*gits.GitUser{
+				"carolgit": {Login: "carolgit", Email: "carol@example.com"},
+			},
+		},
+		JXClient:  jxClient,
+		Namespace: "jx",
+	}
+
+	details, err := r.GitUserSliceAsUserDetailsSliceBatch([]gits.GitUser{{Login: "carolgit"}})
+	assert.NoError(t, err)
+	assert.Len(t, details, 1)
+
+	var found bool
+	for _, a := range details[0].Accounts {
+		if a.Provider == testProviderKey && a.ID == "carolgit" {
+			found = true
+		}
+	}
+	assert.True(t, found, "expected residual-resolved UserDetails to already carry the backfilled AccountReference, got %+v", details[0].Accounts)
+}
+
+func TestGitUserSliceAsUserDetailsSliceBatchEmptyInput(t *testing.T) {
+	jxClient := fake.NewSimpleClientset()
+	r := newTestResolver(jxClient)
+
+	details, err := r.GitUserSliceAsUserDetailsSliceBatch(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, details)
+}
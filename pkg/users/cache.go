@@ -0,0 +1,179 @@
+package users
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// defaultCacheSize bounds the number of entries kept per cache before the least recently used
+	// entry is evicted
+	defaultCacheSize = 2048
+	// defaultCacheTTL is how long a resolved (or negative) lookup is trusted before it is re-fetched
+	defaultCacheTTL = 5 * time.Minute
+	// defaultProviderRate bounds the steady-state rate of GitProvider.UserInfo calls per git provider,
+	// shared by every resolver for that provider
+	defaultProviderRate = 5
+	// defaultProviderBurst allows a short burst above defaultProviderRate, e.g. at the start of a large PR import
+	defaultProviderBurst = 10
+)
+
+var (
+	cacheHitsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "jx_user_resolver_cache_hits_total",
+		Help: "Number of GitUserResolver lookups served from the in-process cache",
+	}, []string{"provider"})
+	cacheMissesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "jx_user_resolver_cache_misses_total",
+		Help: "Number of GitUserResolver lookups not found in the in-process cache",
+	}, []string{"provider"})
+	providerCallsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "jx_user_resolver_provider_calls_total",
+		Help: "Number of calls made to GitProvider.UserInfo by GitUserResolver",
+	}, []string{"provider"})
+	conflictsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "jx_user_resolver_conflicts_total",
+		Help: "Number of GitUserResolver resolutions that found more than one matching user",
+	}, []string{"provider"})
+)
+
+func init() {
+	prometheus.MustRegister(cacheHitsTotal, cacheMissesTotal, providerCallsTotal, conflictsTotal)
+}
+
+// negativeResult marks a userCache entry as a confirmed miss, so repeated lookups for a login that
+// the git provider is known not to resolve don't keep re-issuing UserInfo calls.
+var negativeResult = &struct{}{}
+
+// userCacheKey identifies a cached lookup, scoped to a single git provider so that resolvers for
+// different providers (or different GitHub Enterprise instances) never share entries.
+type userCacheKey struct {
+	providerKey string
+	value       string
+}
+
+type userCacheEntry struct {
+	key       userCacheKey
+	value     interface{}
+	expiresAt time.Time
+	element   *list.Element
+}
+
+// userCache is a small in-process LRU cache with a TTL, keyed by (providerKey, login) or
+// (providerKey, email). It is safe for concurrent use. A single GitUserResolver keeps one
+// userCache for resolved Jenkins X users and a second for raw GitProvider.UserInfo results.
+type userCache struct {
+	mu      sync.Mutex
+	maxSize int
+	ttl     time.Duration
+	entries map[userCacheKey]*userCacheEntry
+	order   *list.List
+}
+
+func newUserCache(maxSize int, ttl time.Duration) *userCache {
+	return &userCache{
+		maxSize: maxSize,
+		ttl:     ttl,
+		entries: make(map[userCacheKey]*userCacheEntry),
+		order:   list.New(),
+	}
+}
+
+// get returns the cached value for key, whether it was found, and whether the match was a
+// negative (known-absent) result.
+func (c *userCache) get(key userCacheKey) (value interface{}, found bool, negative bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		c.removeLocked(entry)
+		return nil, false, false
+	}
+	c.order.MoveToFront(entry.element)
+	if entry.value == negativeResult {
+		return nil, true, true
+	}
+	return entry.value, true, false
+}
+
+// put caches value against key, evicting the least recently used entry if the cache is full. A nil
+// value caches a negative result.
+func (c *userCache) put(key userCacheKey, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if value == nil {
+		value = negativeResult
+	}
+	if existing, ok := c.entries[key]; ok {
+		existing.value = value
+		existing.expiresAt = time.Now().Add(c.ttl)
+		c.order.MoveToFront(existing.element)
+		return
+	}
+	entry := &userCacheEntry{key: key, value: value, expiresAt: time.Now().Add(c.ttl)}
+	entry.element = c.order.PushFront(entry)
+	c.entries[key] = entry
+	for len(c.entries) > c.maxSize {
+		oldest := c.order.Back()
+		if oldest == nil {
+			break
+		}
+		c.removeLocked(oldest.Value.(*userCacheEntry))
+	}
+}
+
+func (c *userCache) removeLocked(entry *userCacheEntry) {
+	c.order.Remove(entry.element)
+	delete(c.entries, entry.key)
+}
+
+// sharedCaches holds the userCache/gitProviderCache/rate.Limiter trio for one git provider.
+type sharedCaches struct {
+	userCache        *userCache
+	gitProviderCache *userCache
+	providerLimiter  *rate.Limiter
+}
+
+// providerCaches is a process-wide registry of sharedCaches, keyed by GitProviderKey. Call sites
+// across jx commonly build a new GitUserResolver per resolution rather than keeping one around for
+// the lifetime of an import, so the cache and rate limiter are hung off this package-level registry
+// instead of the resolver struct itself - otherwise a fresh resolver per commit would mean a fresh,
+// empty cache per commit, which defeats the point during a large PR import.
+var (
+	providerCachesMu sync.Mutex
+	providerCaches   = map[string]*sharedCaches{}
+)
+
+func sharedCachesFor(providerKey string) *sharedCaches {
+	providerCachesMu.Lock()
+	defer providerCachesMu.Unlock()
+	caches, ok := providerCaches[providerKey]
+	if !ok {
+		caches = &sharedCaches{
+			userCache:        newUserCache(defaultCacheSize, defaultCacheTTL),
+			gitProviderCache: newUserCache(defaultCacheSize, defaultCacheTTL),
+			providerLimiter:  rate.NewLimiter(rate.Limit(defaultProviderRate), defaultProviderBurst),
+		}
+		providerCaches[providerKey] = caches
+	}
+	return caches
+}
+
+// ensureCache lazily binds the resolver to the process-wide cache and rate limiter for its
+// GitProviderKey, so that every GitUserResolver for a given provider shares the same cache - even
+// when, as is the norm today, a new GitUserResolver struct is built per resolution.
+func (r *GitUserResolver) ensureCache() {
+	r.cacheOnce.Do(func() {
+		caches := sharedCachesFor(r.GitProviderKey())
+		r.userCache = caches.userCache
+		r.gitProviderCache = caches.gitProviderCache
+		r.providerLimiter = caches.providerLimiter
+	})
+}
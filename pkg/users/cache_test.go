@@ -0,0 +1,65 @@
+package users
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserCachePutGetAndTTLExpiry(t *testing.T) {
+	c := newUserCache(2, 10*time.Millisecond)
+	key := userCacheKey{providerKey: "jenkins.io/git-github-userid", value: "alice"}
+	c.put(key, "alice-value")
+
+	value, found, negative := c.get(key)
+	assert.True(t, found)
+	assert.False(t, negative)
+	assert.Equal(t, "alice-value", value)
+
+	time.Sleep(20 * time.Millisecond)
+	_, found, _ = c.get(key)
+	assert.False(t, found, "entry should have expired")
+}
+
+func TestUserCacheNegativeResult(t *testing.T) {
+	c := newUserCache(2, time.Minute)
+	key := userCacheKey{providerKey: "jenkins.io/git-github-userid", value: "ghost"}
+	c.put(key, nil)
+
+	value, found, negative := c.get(key)
+	assert.True(t, found)
+	assert.True(t, negative)
+	assert.Nil(t, value)
+}
+
+func TestUserCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newUserCache(2, time.Minute)
+	keyA := userCacheKey{value: "a"}
+	keyB := userCacheKey{value: "b"}
+	keyC := userCacheKey{value: "c"}
+
+	c.put(keyA, "a")
+	c.put(keyB, "b")
+	// touch "a" so "b" becomes the least recently used
+	c.get(keyA)
+	c.put(keyC, "c")
+
+	_, found, _ := c.get(keyB)
+	assert.False(t, found, "least recently used entry should have been evicted")
+	_, found, _ = c.get(keyA)
+	assert.True(t, found)
+	_, found, _ = c.get(keyC)
+	assert.True(t, found)
+}
+
+func TestSharedCachesForReusedAcrossResolverInstances(t *testing.T) {
+	a := sharedCachesFor("jenkins.io/git-github-userid")
+	b := sharedCachesFor("jenkins.io/git-github-userid")
+	assert.Same(t, a.userCache, b.userCache)
+	assert.Same(t, a.gitProviderCache, b.gitProviderCache)
+	assert.Same(t, a.providerLimiter, b.providerLimiter)
+
+	other := sharedCachesFor("jenkins.io/git-gitlab-userid")
+	assert.NotSame(t, a.userCache, other.userCache)
+}
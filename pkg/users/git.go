@@ -1,7 +1,9 @@
 package users
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
 
@@ -13,6 +15,7 @@ import (
 	jenkninsv1client "github.com/jenkins-x/jx/pkg/client/clientset/versioned"
 
 	"github.com/jenkins-x/jx/pkg/gits"
+	"golang.org/x/time/rate"
 )
 
 // GitUserResolver allows git users to be converted to Jenkins X users
@@ -20,6 +23,13 @@ type GitUserResolver struct {
 	GitProvider gits.GitProvider
 	JXClient    jenkninsv1client.Interface
 	Namespace   string
+
+	// userCache, gitProviderCache and providerLimiter are lazily initialised by ensureCache so that
+	// a GitUserResolver built as a plain struct literal keeps working without them.
+	cacheOnce        sync.Once
+	userCache        *userCache
+	gitProviderCache *userCache
+	providerLimiter  *rate.Limiter
 }
 
 // GitSignatureAsUser resolves the signature to a Jenkins X User
@@ -52,7 +62,35 @@ func (r *GitUserResolver) GitUserAsUser(user *gits.GitUser) (*jenkinsv1.User, er
 	if user == nil {
 		return nil, fmt.Errorf("user cannot be nil")
 	}
+	r.ensureCache()
 	providerKey := r.GitProviderKey()
+	providerKind := r.GitProvider.Kind()
+
+	if user.Login != "" {
+		cacheKey := userCacheKey{providerKey: providerKey, value: user.Login}
+		if cached, found, _ := r.userCache.get(cacheKey); found {
+			cacheHitsTotal.WithLabelValues(providerKind).Inc()
+			return cached.(*jenkinsv1.User), nil
+		}
+		cacheMissesTotal.WithLabelValues(providerKind).Inc()
+	}
+
+	found, err := r.gitUserAsUserUncached(user, providerKey)
+	if err != nil {
+		return nil, err
+	}
+	if user.Login != "" {
+		r.userCache.put(userCacheKey{providerKey: providerKey, value: user.Login}, found)
+	}
+	if found.Spec.Email != "" {
+		r.userCache.put(userCacheKey{providerKey: providerKey, value: found.Spec.Email}, found)
+	}
+	return found, nil
+}
+
+// gitUserAsUserUncached does the actual resolution work for GitUserAsUser; callers should go
+// through GitUserAsUser so that results are cached.
+func (r *GitUserResolver) gitUserAsUserUncached(user *gits.GitUser, providerKey string) (*jenkinsv1.User, error) {
 	if user.Login != "" {
 
 		labelSelector := fmt.Sprintf("%s=%s", providerKey, user.Login)
@@ -65,6 +103,7 @@ func (r *GitUserResolver) GitUserAsUser(user *gits.GitUser) (*jenkinsv1.User, er
 			return nil, err
 		}
 		if len(users.Items) > 1 {
+			conflictsTotal.WithLabelValues(r.GitProvider.Kind()).Inc()
 			return nil, fmt.Errorf("more than one user found in users.jenkins.io with label %s, found %v", labelSelector,
 				users.Items)
 		} else if len(users.Items) == 1 {
@@ -88,6 +127,7 @@ func (r *GitUserResolver) GitUserAsUser(user *gits.GitUser) (*jenkinsv1.User, er
 			}
 		}
 		if len(possibles) > 1 {
+			conflictsTotal.WithLabelValues(r.GitProvider.Kind()).Inc()
 			possibleUsers := make([]string, 0)
 			for _, p := range possibles {
 				possibleUsers = append(possibleUsers, p.Name)
@@ -111,7 +151,7 @@ func (r *GitUserResolver) GitUserAsUser(user *gits.GitUser) (*jenkinsv1.User, er
 	}
 
 	// Finally, try to resolve by email address against git user, by performing a remote git call
-	gitUser := r.GitProvider.UserInfo(user.Login)
+	gitUser := r.lookupGitProvider(user.Login)
 	possibles := make([]jenkinsv1.User, 0)
 	if gitUser == nil {
 		// annoyingly UserInfo swallows the error, so we recreate it!
@@ -124,6 +164,7 @@ func (r *GitUserResolver) GitUserAsUser(user *gits.GitUser) (*jenkinsv1.User, er
 		}
 	}
 	if len(possibles) > 1 {
+		conflictsTotal.WithLabelValues(r.GitProvider.Kind()).Inc()
 		possibleStrings := make([]string, 0)
 		for _, p := range possibles {
 			possibleStrings = append(possibleStrings, p.Name)
@@ -154,6 +195,18 @@ func (r *GitUserResolver) GitUserAsUser(user *gits.GitUser) (*jenkinsv1.User, er
 		}
 		return found, nil
 	} else {
+		if user.Login != "" {
+			// Give the IdentityResolver, backed by this git provider alone, the chance to merge this
+			// login into an existing User that already claims it or its email, or to create the User
+			// itself; it returns nil when the git provider does not recognise the login.
+			resolved, err := r.IdentityResolver().ResolveByExternalID(context.Background(), user.Login)
+			if err != nil {
+				return nil, err
+			}
+			if resolved != nil {
+				return resolved, nil
+			}
+		}
 		// Otherwise, create a new user using the best info we have
 		// gitUser (looked up using the git provider API) is the default,
 		// but let's see if anything from user we were passed in as an argument can help
@@ -163,6 +216,16 @@ func (r *GitUserResolver) GitUserAsUser(user *gits.GitUser) (*jenkinsv1.User, er
 	return nil, nil
 }
 
+// IdentityResolver returns an IdentityResolver backed solely by this GitUserResolver's git
+// provider, so GitUserAsUser's final create-or-merge step goes through the same cross-provider
+// merge logic (ResolverBackend registry, conflict quarantine) that a multi-backend IdentityResolver
+// uses, making GitUserAsUser the specific single-provider consumer described on IdentityResolver.
+func (r *GitUserResolver) IdentityResolver() *IdentityResolver {
+	ir := &IdentityResolver{JXClient: r.JXClient, Namespace: r.Namespace}
+	ir.RegisterBackend(NewGitProviderBackend(r), 0)
+	return ir
+}
+
 // UpdateUserFromPRAuthor will attempt to use the
 func (r *GitUserResolver) UpdateUserFromPRAuthor(author *jenkinsv1.User, pullRequest *gits.GitPullRequest,
 	commits []*gits.GitCommit) (*jenkinsv1.User, error) {
@@ -191,6 +254,37 @@ func (r *GitUserResolver) UpdateUserFromPRAuthor(author *jenkinsv1.User, pullReq
 	return author, nil
 }
 
+// lookupGitProvider resolves login against the GitProvider, behind a token-bucket rate limiter
+// shared by every resolver for this git provider and a negative-result cache, so that repeated
+// lookups for the same unknown login
+// don't keep hammering the git API.
+func (r *GitUserResolver) lookupGitProvider(login string) *gits.GitUser {
+	providerKey := r.GitProviderKey()
+	providerKind := r.GitProvider.Kind()
+	cacheKey := userCacheKey{providerKey: providerKey, value: login}
+
+	if cached, found, negative := r.gitProviderCache.get(cacheKey); found {
+		cacheHitsTotal.WithLabelValues(providerKind).Inc()
+		if negative {
+			return nil
+		}
+		return cached.(*gits.GitUser)
+	}
+	cacheMissesTotal.WithLabelValues(providerKind).Inc()
+
+	if err := r.providerLimiter.Wait(context.Background()); err != nil {
+		log.Warnf("rate limiter wait for GitProvider %s aborted: %v\n", providerKind, err)
+	}
+	providerCallsTotal.WithLabelValues(providerKind).Inc()
+	gitUser := r.GitProvider.UserInfo(login)
+	if gitUser == nil {
+		r.gitProviderCache.put(cacheKey, nil)
+		return nil
+	}
+	r.gitProviderCache.put(cacheKey, gitUser)
+	return gitUser
+}
+
 // UserToGitUser performs type conversion from a Jenkins X User to a Git User
 func (r *GitUserResolver) UserToGitUser(id string, user *jenkinsv1.User) *gits.GitUser {
 	return &gits.GitUser{
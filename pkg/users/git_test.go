@@ -0,0 +1,54 @@
+package users
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jenkins-x/jx/pkg/gits"
+
+	fake "github.com/jenkins-x/jx/pkg/client/clientset/versioned/fake"
+)
+
+func TestGitUserResolverIdentityResolverResolvesThroughGitProviderBackend(t *testing.T) {
+	jxClient := fake.NewSimpleClientset()
+	r := &GitUserResolver{
+		GitProvider: &stubGitProvider{
+			kind: testProviderKind,
+			users: map[string]*gits.GitUser{
+				"dave": {Login: "dave", Email: "dave@example.com", Name: "Dave"},
+			},
+		},
+		JXClient:  jxClient,
+		Namespace: "jx",
+	}
+
+	user, err := r.IdentityResolver().ResolveByExternalID(context.Background(), "dave")
+	assert.NoError(t, err)
+	if assert.NotNil(t, user) {
+		assert.Equal(t, "dave@example.com", user.Spec.Email)
+		var found bool
+		for _, a := range user.Spec.Accounts {
+			if a.Provider == testProviderKey && a.ID == "dave" {
+				found = true
+			}
+		}
+		assert.True(t, found)
+	}
+}
+
+func TestGitUserAsUserFallsBackToPlainCreateWhenIdentityResolverHasNoMatch(t *testing.T) {
+	jxClient := fake.NewSimpleClientset()
+	r := &GitUserResolver{
+		GitProvider: &stubGitProvider{kind: testProviderKind},
+		JXClient:    jxClient,
+		Namespace:   "jx",
+	}
+
+	user, err := r.GitUserAsUser(&gits.GitUser{Login: "erin", Email: "erin@example.com", Name: "Erin"})
+	assert.NoError(t, err)
+	if assert.NotNil(t, user) {
+		assert.Equal(t, "erin@example.com", user.Spec.Email)
+	}
+}
@@ -0,0 +1,215 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	jenkinsv1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/log"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	jenkninsv1client "github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+)
+
+// ExternalIdentity is a single provider's view of a person, as returned by a ResolverBackend.
+type ExternalIdentity struct {
+	// Provider is the backend's AccountReference provider key, e.g. "jenkins.io/git-github-userid"
+	Provider string
+	// ID is the provider-specific identifier for this identity (a login, DN, or subject)
+	ID        string
+	Email     string
+	Name      string
+	URL       string
+	AvatarURL string
+	// Labels are provider-scoped labels to merge onto the resulting User, e.g. team or group membership
+	Labels map[string]string
+}
+
+// ResolverBackend looks up a single provider's view of an identity, keyed by an opaque hint
+// (a login, an email address, or any other identifier the backend understands).
+type ResolverBackend interface {
+	// Name returns a stable, unique name for this backend, used as the AccountReference provider key
+	Name() string
+	// Lookup resolves hint to an ExternalIdentity. It returns (nil, nil) if the backend has no
+	// opinion about hint, rather than an error, so that a miss in one backend doesn't abort resolution.
+	Lookup(ctx context.Context, hint string) (*ExternalIdentity, error)
+}
+
+// backendRegistration pairs a ResolverBackend with the priority used to resolve conflicting identities.
+type backendRegistration struct {
+	backend  ResolverBackend
+	priority int
+}
+
+// IdentityResolver merges accounts from multiple ResolverBackends (GitHub, GitLab, Bitbucket,
+// Jenkins, LDAP, OIDC, ...) into a single jenkinsv1.User. GitUserResolver.GitUserAsUser is one
+// specific consumer of this, wrapping a single git provider as a ResolverBackend via
+// NewGitProviderBackend.
+type IdentityResolver struct {
+	JXClient  jenkninsv1client.Interface
+	Namespace string
+
+	backends []backendRegistration
+}
+
+// RegisterBackend adds backend to the registry at the given priority. The lowest-priority-number
+// backend that recognises an identity supplies the ID, name and email of a newly created User; when
+// it and another backend both supply an email and the two differ, the identity is quarantined (see
+// ErrIdentityQuarantined). Equal priority never arises in practice as backends are expected to use
+// distinct priorities.
+func (r *IdentityResolver) RegisterBackend(backend ResolverBackend, priority int) {
+	r.backends = append(r.backends, backendRegistration{backend: backend, priority: priority})
+	sort.SliceStable(r.backends, func(i, j int) bool {
+		return r.backends[i].priority < r.backends[j].priority
+	})
+}
+
+// ErrIdentityQuarantined is returned by ResolveByEmail/ResolveByExternalID when two or more
+// backends return identities that disagree on the canonical email, and the conflict could not be
+// resolved by priority order alone (both identities supplied a non-empty, differing email).
+type ErrIdentityQuarantined struct {
+	Hint       string
+	Identities []ExternalIdentity
+}
+
+// Error implements error
+func (e *ErrIdentityQuarantined) Error() string {
+	return fmt.Sprintf("identity for %q is quarantined: %d backends returned conflicting identities", e.Hint, len(e.Identities))
+}
+
+// ResolveByEmail merges every backend's view of email into a single jenkinsv1.User, creating or
+// updating the user custom resource as needed. It returns (nil, nil) if no backend recognises email.
+func (r *IdentityResolver) ResolveByEmail(ctx context.Context, email string) (*jenkinsv1.User, error) {
+	return r.resolve(ctx, email)
+}
+
+// ResolveByExternalID merges every backend's view of externalID (a provider-specific login or
+// subject) into a single jenkinsv1.User. It returns (nil, nil) if no backend recognises externalID.
+func (r *IdentityResolver) ResolveByExternalID(ctx context.Context, externalID string) (*jenkinsv1.User, error) {
+	return r.resolve(ctx, externalID)
+}
+
+func (r *IdentityResolver) resolve(ctx context.Context, hint string) (*jenkinsv1.User, error) {
+	identities := make([]ExternalIdentity, 0, len(r.backends))
+	for _, reg := range r.backends {
+		identity, err := reg.backend.Lookup(ctx, hint)
+		if err != nil {
+			log.Warnf("identity backend %s failed to resolve %q: %v\n", reg.backend.Name(), hint, err)
+			continue
+		}
+		if identity == nil {
+			continue
+		}
+		identities = append(identities, *identity)
+	}
+	if len(identities) == 0 {
+		return nil, nil
+	}
+
+	canonical := identities[0]
+	for _, identity := range identities[1:] {
+		if identity.Email != "" && canonical.Email != "" && identity.Email != canonical.Email {
+			return nil, &ErrIdentityQuarantined{Hint: hint, Identities: identities}
+		}
+	}
+
+	existing, err := r.findExistingUser(identities)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return r.JXClient.JenkinsV1().Users(r.Namespace).Update(mergeIdentitiesIntoUser(existing, identities))
+	}
+
+	user := CreateUser(r.Namespace, canonical.ID, canonical.Name, canonical.Email)
+	return r.JXClient.JenkinsV1().Users(r.Namespace).Create(mergeIdentitiesIntoUser(user, identities))
+}
+
+// findExistingUser looks for a previously-created jenkinsv1.User that already claims one of
+// identities, either via a matching AccountReference or a matching email, so that resolving the
+// same contributor twice (the normal case across many PRs) updates the existing User rather than
+// creating a duplicate.
+func (r *IdentityResolver) findExistingUser(identities []ExternalIdentity) (*jenkinsv1.User, error) {
+	users, err := r.JXClient.JenkinsV1().Users(r.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range users.Items {
+		u := &users.Items[i]
+		for _, identity := range identities {
+			for _, a := range u.Spec.Accounts {
+				if a.Provider == identity.Provider && a.ID == identity.ID {
+					return u, nil
+				}
+			}
+			if identity.Email != "" && u.Spec.Email == identity.Email {
+				return u, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// mergeIdentitiesIntoUser adds an AccountReference for each identity whose provider user has no
+// account for yet, leaving its existing accounts untouched, and sets every label of identities on
+// user, replacing any existing value under the same key.
+func mergeIdentitiesIntoUser(user *jenkinsv1.User, identities []ExternalIdentity) *jenkinsv1.User {
+	hasAccount := make(map[string]bool, len(user.Spec.Accounts))
+	for _, a := range user.Spec.Accounts {
+		hasAccount[a.Provider] = true
+	}
+	for _, identity := range identities {
+		if !hasAccount[identity.Provider] {
+			user = AddAccountReference(user, identity.Provider, identity.ID)
+			hasAccount[identity.Provider] = true
+		}
+		if len(identity.Labels) > 0 {
+			if user.Labels == nil {
+				user.Labels = make(map[string]string)
+			}
+			for k, v := range identity.Labels {
+				user.Labels[k] = v
+			}
+		}
+	}
+	return user
+}
+
+// gitProviderBackend adapts a GitUserResolver into a ResolverBackend, so a single git provider
+// (GitHub, GitLab, Bitbucket) can be plugged into an IdentityResolver alongside other backends.
+type gitProviderBackend struct {
+	resolver *GitUserResolver
+}
+
+// NewGitProviderBackend wraps resolver as a ResolverBackend keyed by its GitProviderKey.
+func NewGitProviderBackend(resolver *GitUserResolver) ResolverBackend {
+	return &gitProviderBackend{resolver: resolver}
+}
+
+// Name implements ResolverBackend
+func (b *gitProviderBackend) Name() string {
+	return b.resolver.GitProviderKey()
+}
+
+// Lookup implements ResolverBackend by treating hint as a git login and asking the GitProvider
+// directly, through GitUserResolver's cache and rate limiter. Unlike GitUserAsUser, this never
+// reads or writes the Users custom resource itself - IdentityResolver.resolve owns that - so
+// registering the same GitUserResolver as both a ResolverBackend and a direct GitUserAsUser caller
+// can't race to create two User objects for the same person.
+func (b *gitProviderBackend) Lookup(ctx context.Context, hint string) (*ExternalIdentity, error) {
+	b.resolver.ensureCache()
+	gitUser := b.resolver.lookupGitProvider(hint)
+	if gitUser == nil {
+		return nil, nil
+	}
+	return &ExternalIdentity{
+		Provider:  b.resolver.GitProviderKey(),
+		ID:        hint,
+		Email:     gitUser.Email,
+		Name:      gitUser.Name,
+		URL:       gitUser.URL,
+		AvatarURL: gitUser.AvatarURL,
+	}, nil
+}
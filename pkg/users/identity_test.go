@@ -0,0 +1,97 @@
+package users
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fake "github.com/jenkins-x/jx/pkg/client/clientset/versioned/fake"
+)
+
+// fakeBackend is a ResolverBackend test double that answers a single fixed identity for a single
+// fixed hint, and nil for anything else.
+type fakeBackend struct {
+	name     string
+	hint     string
+	identity *ExternalIdentity
+}
+
+func (f *fakeBackend) Name() string {
+	return f.name
+}
+
+func (f *fakeBackend) Lookup(ctx context.Context, hint string) (*ExternalIdentity, error) {
+	if hint != f.hint {
+		return nil, nil
+	}
+	return f.identity, nil
+}
+
+func TestIdentityResolverMergesAcrossBackendsAndDedupesOnReResolve(t *testing.T) {
+	jxClient := fake.NewSimpleClientset()
+	resolver := &IdentityResolver{JXClient: jxClient, Namespace: "jx"}
+	resolver.RegisterBackend(&fakeBackend{
+		name: "jenkins.io/git-github-userid",
+		hint: "alice@example.com",
+		identity: &ExternalIdentity{
+			Provider: "jenkins.io/git-github-userid",
+			ID:       "alice",
+			Email:    "alice@example.com",
+			Name:     "Alice",
+		},
+	}, 1)
+	resolver.RegisterBackend(&fakeBackend{
+		name: "jenkins.io/ldap-userid",
+		hint: "alice@example.com",
+		identity: &ExternalIdentity{
+			Provider: "jenkins.io/ldap-userid",
+			ID:       "alice@example.com",
+			Email:    "alice@example.com",
+			Name:     "Alice A",
+		},
+	}, 2)
+
+	first, err := resolver.ResolveByEmail(context.Background(), "alice@example.com")
+	assert.NoError(t, err)
+	assert.NotNil(t, first)
+	assert.Len(t, first.Spec.Accounts, 2)
+
+	second, err := resolver.ResolveByEmail(context.Background(), "alice@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, first.Name, second.Name)
+
+	list, err := jxClient.JenkinsV1().Users("jx").List(metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, list.Items, 1, "resolving the same identity twice must not create a duplicate User")
+}
+
+func TestIdentityResolverQuarantinesConflictingEmails(t *testing.T) {
+	jxClient := fake.NewSimpleClientset()
+	resolver := &IdentityResolver{JXClient: jxClient, Namespace: "jx"}
+	resolver.RegisterBackend(&fakeBackend{
+		name: "jenkins.io/git-github-userid",
+		hint: "bob",
+		identity: &ExternalIdentity{
+			Provider: "jenkins.io/git-github-userid",
+			ID:       "bob",
+			Email:    "bob@example.com",
+		},
+	}, 1)
+	resolver.RegisterBackend(&fakeBackend{
+		name: "jenkins.io/ldap-userid",
+		hint: "bob",
+		identity: &ExternalIdentity{
+			Provider: "jenkins.io/ldap-userid",
+			ID:       "bob",
+			Email:    "robert@example.com",
+		},
+	}, 2)
+
+	_, err := resolver.ResolveByExternalID(context.Background(), "bob")
+	assert.Error(t, err)
+	_, ok := err.(*ErrIdentityQuarantined)
+	assert.True(t, ok, "expected an ErrIdentityQuarantined, got %T", err)
+}